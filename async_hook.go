@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	asyncHookDefaultBufferSize = 1024
+	asyncHookDefaultWorkers    = 1
+	asyncHookWarnInterval      = 10 * time.Second
+)
+
+/*
+AsyncHook fronts any logrus.Hook with a bounded buffered channel and N worker goroutines that drain it, so a slow
+inner hook (typically a blocking network call) can never stall the goroutine doing the logging.
+
+When the buffer is full, the oldest queued entry is dropped to make room for the new one and the drop counter
+returned by Dropped is incremented; a synchronous warning summarizing the drop count is logged at most once per
+asyncHookWarnInterval, so sustained overflow is visible without flooding the log itself.
+
+Only wrap a hook whose Fire does not depend on running on the original logging goroutine: Fire executes on a
+worker goroutine, so any information the inner hook derives by inspecting its own call stack (e.g. logrus_sentry's
+stacktrace capture) will reflect the worker, not the caller.
+ */
+type AsyncHook struct {
+	inner   logrus.Hook
+	entries chan *logrus.Entry
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	pending       int64 // atomic: entries queued or currently being handed to inner
+	dropped       uint64
+	warnedDropped uint64
+	lastWarnedAt  int64 // unix nano, accessed atomically
+}
+
+/*
+NewAsyncHook wraps inner in an AsyncHook, sized from LOGGER_ASYNC_BUFFER (default 1024) and drained by a single
+worker goroutine.
+ */
+func NewAsyncHook(inner logrus.Hook) *AsyncHook {
+	return NewAsyncHookWithWorkers(inner, intFromEnv("LOGGER_ASYNC_BUFFER", asyncHookDefaultBufferSize), asyncHookDefaultWorkers)
+}
+
+/*
+NewAsyncHookWithWorkers wraps inner in an AsyncHook with a buffer of bufferSize entries, drained by workers
+goroutines. Useful for applications that want more delivery throughput than the default single worker. Both
+bufferSize and workers are clamped to a minimum of 1: an unbuffered channel would make Fire spin on the caller's
+goroutine whenever the workers are busy, defeating the point of the wrapper.
+ */
+func NewAsyncHookWithWorkers(inner logrus.Hook, bufferSize int, workers int) *AsyncHook {
+	if workers < 1 {
+		workers = 1
+	}
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	hook := &AsyncHook{
+		inner:   inner,
+		entries: make(chan *logrus.Entry, bufferSize),
+		stop:    make(chan struct{}),
+	}
+
+	hook.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go hook.drain()
+	}
+	return hook
+}
+
+/*
+Levels implements logrus.Hook: AsyncHook ships whatever levels the wrapped hook is interested in
+ */
+func (hook *AsyncHook) Levels() []logrus.Level {
+	return hook.inner.Levels()
+}
+
+/*
+Fire implements logrus.Hook: it enqueues the entry without blocking, dropping the oldest queued entry to make
+room when the buffer is full
+ */
+func (hook *AsyncHook) Fire(entry *logrus.Entry) error {
+	for {
+		select {
+		case hook.entries <- entry:
+			atomic.AddInt64(&hook.pending, 1)
+			return nil
+		default:
+		}
+
+		select {
+		case <-hook.entries:
+			atomic.AddInt64(&hook.pending, -1)
+			atomic.AddUint64(&hook.dropped, 1)
+			hook.warnOnDrop()
+		default:
+		}
+	}
+}
+
+/*
+warnOnDrop logs a summary of the drop count directly to stderr, at most once per asyncHookWarnInterval. It
+deliberately bypasses the package logger: an AsyncHook may itself be wired into that logger, so logging a warning
+through WithField(...).Warning(...) could re-enter this very AsyncHook's Fire from inside the caller's own Fire
+call, stealing the buffer slot just freed for the real entry.
+ */
+func (hook *AsyncHook) warnOnDrop() {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&hook.lastWarnedAt)
+	if now-last < int64(asyncHookWarnInterval) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&hook.lastWarnedAt, last, now) {
+		return
+	}
+
+	dropped := atomic.LoadUint64(&hook.dropped)
+	warnedAt := atomic.SwapUint64(&hook.warnedDropped, dropped)
+	fmt.Fprintf(os.Stderr, "logger: AsyncHook buffer is full, dropped %d log entries\n", dropped-warnedAt)
+}
+
+/*
+drain is the worker loop draining hook.entries into the wrapped hook. Several workers may run this concurrently.
+It exits once Close is called and every already-queued entry has been handed to the wrapped hook.
+ */
+func (hook *AsyncHook) drain() {
+	defer hook.wg.Done()
+	for {
+		select {
+		case entry := <-hook.entries:
+			hook.inner.Fire(entry)
+			atomic.AddInt64(&hook.pending, -1)
+		case <-hook.stop:
+			for {
+				select {
+				case entry := <-hook.entries:
+					hook.inner.Fire(entry)
+					atomic.AddInt64(&hook.pending, -1)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+/*
+Flush blocks until every entry queued so far has been fully handed to the wrapped hook, or ctx expires. Intended
+for graceful shutdown.
+ */
+func (hook *AsyncHook) Flush(ctx context.Context) error {
+	for atomic.LoadInt64(&hook.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return nil
+}
+
+/*
+Dropped returns the total number of entries dropped because the buffer was full since this hook was created.
+ */
+func (hook *AsyncHook) Dropped() uint64 {
+	return atomic.LoadUint64(&hook.dropped)
+}
+
+/*
+Close stops every worker goroutine, after each has drained whatever was already queued in hook.entries. Meant for
+retiring a hook that is no longer reachable from any logger (e.g. when ReloadConfiguration replaces the
+singleton); entries queued after Close are simply never delivered. It blocks until every worker has exited.
+ */
+func (hook *AsyncHook) Close() {
+	close(hook.stop)
+	hook.wg.Wait()
+}
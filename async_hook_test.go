@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+blockingHook is a test logrus.Hook that blocks on Fire until unblocked, so we can exercise AsyncHook's buffering
+and overflow behavior deterministically.
+ */
+type blockingHook struct {
+	mu      sync.Mutex
+	fired   []*logrus.Entry
+	release chan struct{}
+}
+
+func newBlockingHook() *blockingHook {
+	return &blockingHook{release: make(chan struct{})}
+}
+
+func (h *blockingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *blockingHook) Fire(entry *logrus.Entry) error {
+	<-h.release
+	h.mu.Lock()
+	h.fired = append(h.fired, entry)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *blockingHook) firedCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.fired)
+}
+
+/*
+Tests that Fire never blocks the caller, even once the buffer is full and the inner hook is stuck
+ */
+func TestAsyncHook_FireNeverBlocks(t *testing.T) {
+	inner := newBlockingHook()
+	hook := NewAsyncHookWithWorkers(inner, 4, 1)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			hook.Fire(&logrus.Entry{Message: "entry"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Fire blocked while the inner hook was stuck")
+	}
+
+	assert.True(t, hook.Dropped() > 0)
+	close(inner.release)
+	hook.Close()
+}
+
+/*
+Tests that Flush waits until the inner hook has drained the queue
+ */
+func TestAsyncHook_Flush(t *testing.T) {
+	inner := newBlockingHook()
+	hook := NewAsyncHookWithWorkers(inner, 4, 1)
+
+	hook.Fire(&logrus.Entry{Message: "entry"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, hook.Flush(ctx), "Flush should time out while the inner hook is stuck")
+
+	close(inner.release)
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel2()
+	assert.Nil(t, hook.Flush(ctx2))
+	assert.Equal(t, 1, inner.firedCount())
+
+	hook.Close()
+}
+
+/*
+Tests that Close drains whatever was already queued before stopping the worker goroutines, and returns once they
+have all exited
+ */
+func TestAsyncHook_Close(t *testing.T) {
+	inner := newBlockingHook()
+	close(inner.release)
+	hook := NewAsyncHookWithWorkers(inner, 4, 1)
+
+	hook.Fire(&logrus.Entry{Message: "entry"})
+
+	done := make(chan struct{})
+	go func() {
+		hook.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return once the worker drained and exited")
+	}
+
+	assert.Equal(t, 1, inner.firedCount())
+}
@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	logentriesDefaultHost = "data.logentries.com"
+	logentriesDefaultPort = "443"
+	logentriesBufferSize  = 1024
+	logentriesDialTimeout = 5 * time.Second
+	logentriesMinBackoff  = time.Second
+	logentriesMaxBackoff  = 30 * time.Second
+)
+
+// logentriesDropped counts entries that could not be delivered to Logentries, whether because the in-memory
+// buffer was full or because the connection failed. Exposed read-only through Stats().
+var logentriesDropped uint64
+
+/*
+LoggerStats holds counters useful to diagnose lossy delivery to external log sinks.
+ */
+type LoggerStats struct {
+	LogentriesDropped uint64
+}
+
+/*
+Stats returns a snapshot of the counters tracking delivery to external log sinks.
+ */
+func Stats() LoggerStats {
+	return LoggerStats{LogentriesDropped: atomic.LoadUint64(&logentriesDropped)}
+}
+
+/*
+LogentriesHook ships each log entry to Logentries over a persistent TLS connection, using the Logentries
+token-TCP protocol: each JSON-serialized entry is prefixed with the account token and terminated with a newline.
+Entries are queued in a bounded in-memory buffer and written from a background goroutine that reconnects with
+backoff whenever the connection is lost, so a downed Logentries endpoint never blocks callers. Entries that
+cannot be delivered are written to stderr instead and counted in Stats().
+ */
+type LogentriesHook struct {
+	token     string
+	addr      string
+	formatter logrus.Formatter
+	entries   chan []byte
+	stop      chan struct{}
+}
+
+/*
+createLogentriesHookFromEnv builds a LogentriesHook from LOGENTRIES_TOKEN/LOGENTRIES_HOST/LOGENTRIES_PORT. It
+returns nil when LOGENTRIES_TOKEN is not set.
+ */
+func createLogentriesHookFromEnv() *LogentriesHook {
+	token := os.Getenv("LOGENTRIES_TOKEN")
+	if token == "" {
+		return nil
+	}
+
+	host := os.Getenv("LOGENTRIES_HOST")
+	if host == "" {
+		host = logentriesDefaultHost
+	}
+	port := os.Getenv("LOGENTRIES_PORT")
+	if port == "" {
+		port = logentriesDefaultPort
+	}
+
+	return NewLogentriesHook(token, fmt.Sprintf("%s:%s", host, port))
+}
+
+/*
+NewLogentriesHook creates a LogentriesHook shipping entries tagged with token to addr. It starts the background
+delivery goroutine immediately.
+ */
+func NewLogentriesHook(token string, addr string) *LogentriesHook {
+	hook := &LogentriesHook{
+		token:     token,
+		addr:      addr,
+		formatter: new(logrus.JSONFormatter),
+		entries:   make(chan []byte, logentriesBufferSize),
+		stop:      make(chan struct{}),
+	}
+	go hook.run()
+	return hook
+}
+
+/*
+Levels implements logrus.Hook: every level is shipped to Logentries
+ */
+func (hook *LogentriesHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+/*
+Fire implements logrus.Hook: it frames the entry as "<token> <json>\n" and enqueues it without blocking; if the
+buffer is already full, the entry is written to stderr and counted as dropped instead
+ */
+func (hook *LogentriesHook) Fire(entry *logrus.Entry) error {
+	serialized, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	framed := make([]byte, 0, len(hook.token)+1+len(serialized))
+	framed = append(framed, hook.token...)
+	framed = append(framed, ' ')
+	framed = append(framed, serialized...)
+
+	select {
+	case hook.entries <- framed:
+	default:
+		hook.fallback(framed, fmt.Errorf("buffer full"))
+	}
+	return nil
+}
+
+/*
+run owns the TLS connection to Logentries. It dials lazily on the first queued entry and redials with an
+exponential backoff (capped at logentriesMaxBackoff) whenever the connection cannot be established or a write
+fails. Entries that cannot be delivered are written to stderr and counted instead of being retried, so the
+buffer never grows unbounded. It exits, closing the connection, as soon as Close is called.
+ */
+func (hook *LogentriesHook) run() {
+	var conn net.Conn
+	backoff := logentriesMinBackoff
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for {
+		select {
+		case framed := <-hook.entries:
+			if conn == nil {
+				var err error
+				conn, err = tls.DialWithDialer(&net.Dialer{Timeout: logentriesDialTimeout}, "tcp", hook.addr, nil)
+				if err != nil {
+					hook.fallback(framed, err)
+					select {
+					case <-time.After(backoff):
+					case <-hook.stop:
+						return
+					}
+					backoff = nextLogentriesBackoff(backoff)
+					continue
+				}
+				backoff = logentriesMinBackoff
+			}
+
+			if _, err := conn.Write(framed); err != nil {
+				hook.fallback(framed, err)
+				conn.Close()
+				conn = nil
+			}
+		case <-hook.stop:
+			return
+		}
+	}
+}
+
+/*
+Close stops the background delivery goroutine and closes any open connection. Meant for retiring a hook that is
+no longer reachable from any logger (e.g. when ReloadConfiguration replaces the singleton); entries queued after
+Close are simply never delivered.
+ */
+func (hook *LogentriesHook) Close() {
+	close(hook.stop)
+}
+
+func nextLogentriesBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next > logentriesMaxBackoff {
+		return logentriesMaxBackoff
+	}
+	return next
+}
+
+/*
+fallback counts the drop and logs the entry payload to stderr, stripping the leading token so the account
+credential is never written to the process's log stream.
+ */
+func (hook *LogentriesHook) fallback(framed []byte, err error) {
+	atomic.AddUint64(&logentriesDropped, 1)
+	payload := framed[len(hook.token)+1:]
+	fmt.Fprintf(os.Stderr, "logger: failed to deliver entry to Logentries (%v): %s", err, payload)
+}
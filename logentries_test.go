@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Tests that a LogentriesHook with no reachable endpoint never blocks Fire, and that the dropped entries are
+reflected in Stats()
+ */
+func TestLogentriesHook_DoesNotBlockAndCountsDrops(t *testing.T) {
+	before := Stats().LogentriesDropped
+
+	hook := NewLogentriesHook("TESTTOKEN", "127.0.0.1:1")
+	defer hook.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < logentriesBufferSize+10; i++ {
+			hook.Fire(&logrus.Entry{Message: "overflow", Data: logrus.Fields{}})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Fire blocked on an unreachable Logentries endpoint")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, Stats().LogentriesDropped > before)
+}
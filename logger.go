@@ -44,17 +44,62 @@ Configuration
 
 SENTRY_DSN: If provided, warning and error logs will be sent to sentry.
 
+SENTRY_TIMEOUT: Timeout for delivering an event to Sentry, as a Go duration (e.g. "1s"). Defaults to "1s".
+
+SENTRY_STACKTRACE_LEVEL: Minimum level at which a stacktrace is attached to the event. Defaults to "error".
+
+SENTRY_STACKTRACE_CONTEXT: Number of lines of context code displayed around each line of the stack trace. Defaults
+to 12.
+
+SENTRY_STACKTRACE_SKIP: Number of innermost stack frames to skip so the trace starts where logger.Error(...) was
+called. Defaults to 4.
+
+SENTRY_TAGS: Comma-separated list of key=value pairs attached as tags to every event, e.g. "region=eu,az=eu-west-1a".
+
+SENTRY_RELEASE, SENTRY_ENVIRONMENT, SENTRY_SERVER_NAME: Mapped to the matching raven-go client settings.
+
+Log entries can also override where some of that metadata ends up by setting the fields "server_name", "logger",
+"http_request" or "event_id": those are promoted out of Extra into the corresponding Sentry fields instead of being
+shipped as arbitrary metadata. See NewSentryHookWithClient to share a pre-configured raven.Client (useful in tests,
+or to reuse one DSN across several libraries).
+
+The Sentry hook fires synchronously, bounded by SENTRY_TIMEOUT, so a slow or unreachable Sentry endpoint can delay
+but not indefinitely block logging calls. It is deliberately not wrapped in an AsyncHook: logrus_sentry builds its
+stacktrace lazily, inside Fire, by walking the calling goroutine's stack, so moving Fire onto an AsyncHook worker
+goroutine would silently replace every event's stacktrace with the worker's own. Wrap your own hooks with
+NewAsyncHook/NewAsyncHookWithWorkers when they do not depend on being fired from the original caller's goroutine.
+
+LOGGER_ASYNC_BUFFER: Size of the buffer NewAsyncHook uses by default. Defaults to 1024. Once full, the oldest
+queued entry is dropped to make room for new ones; use AsyncHook.Dropped() to observe this.
+
 LOGGER_LEVEL: The minimum level of the message to be actually logged.
 Possible values: "debug" (default, convenient for development), "info", "warning" or "error". If an invalid value
 is provided, "info" will be used and a warning will be logged.
 
-LOGGER_NAME - not yet implemented - The name of the logger.
+LOGGER_NAME: The name tagged on the root logger for sinks that need it (logstash's "type" field). Code that wants
+finer-grained, independently-leveled loggers should use GetNamed instead (see below).
+
+LOGGER_LEVELS: Seeds per-name log levels for the GetNamed registry, as a comma-separated list of name=level pairs,
+e.g. "root=info,billing=debug,billing.invoicing=warning". A named logger inherits its effective level from its
+nearest dotted ancestor with an explicit level, falling back to LOGGER_LEVEL when none match. SetLevel and
+ResetLevels adjust levels at runtime.
+
+LOGENTRIES_TOKEN: If provided, logs will be sent to Logentries over a persistent TLS connection, using the
+Logentries token-TCP protocol. Delivery never blocks the caller: entries are queued and a dropped-entry counter is
+available through Stats().
 
-LOGENTRIES_TOKEN - not yet implemented - If provided, logs will be sent to logentries.
+LOGENTRIES_HOST: Overrides the Logentries endpoint host. Defaults to "data.logentries.com".
 
-LOGSTASH_HOST - not yet implemented - If provided, logs will be sent to logstash.
+LOGENTRIES_PORT: Overrides the Logentries endpoint port. Defaults to "443".
 
-LOGSTASH_PORT - not yet implemented - Mandatory if LOGSTASH_HOST is provided.
+LOGGER_FORMAT: Selects the formatter used for the Out writer. Possible values: "text" (default), "json" or
+"logstash". Use RegisterFormatter to plug in a custom formatter under another name.
+
+LOGSTASH_HOST: If provided, logs will additionally be shipped to a Logstash instance over TCP or UDP.
+
+LOGSTASH_PORT: Mandatory if LOGSTASH_HOST is provided.
+
+LOGSTASH_PROTOCOL: "tcp" (default) or "udp". Only used when LOGSTASH_HOST is provided.
 
 
 Notes
@@ -67,10 +112,9 @@ package logger
 import (
 	"github.com/Sirupsen/logrus"
 	"os"
-	"github.com/evalphobia/logrus_sentry"
-	"time"
 	"strings"
 	"fmt"
+	"sync"
 )
 
 /*
@@ -84,30 +128,76 @@ type Fields logrus.Fields
 var logger *logrus.Logger
 
 /*
-Creates a sentry hook catching message of level warning or worse and sending them to sentry
+closer is implemented by the hooks that own a background goroutine and a network connection: LogstashHook and
+LogentriesHook. activeHooks tracks every instance CreateLogger wired into the current singleton, so
+ReloadConfiguration can stop them instead of leaking them.
  */
-func createSentryHook(sentryDsn string) logrus.Hook {
-	hook, err := logrus_sentry.NewSentryHook(sentryDsn, []logrus.Level{
-		logrus.PanicLevel,
-		logrus.FatalLevel,
-		logrus.ErrorLevel,
-		logrus.WarnLevel,
-	})
-	if err != nil {
-		panic(err)
+type closer interface {
+	Close()
+}
+
+var activeHooksMutex sync.Mutex
+var activeHooks []closer
+
+func registerActiveHook(hook closer) {
+	activeHooksMutex.Lock()
+	activeHooks = append(activeHooks, hook)
+	activeHooksMutex.Unlock()
+}
+
+func closeActiveHooks() {
+	activeHooksMutex.Lock()
+	hooks := activeHooks
+	activeHooks = nil
+	activeHooksMutex.Unlock()
+
+	for _, hook := range hooks {
+		hook.Close()
 	}
-	hook.Timeout = time.Second
-	hook.StacktraceConfiguration.Enable = true
-	hook.StacktraceConfiguration.Level = logrus.ErrorLevel
+}
 
-	// Number of lines of context code displayed around each line of the stack trace. 12 is a comfortable
-	// amount, and there is no need to make this configurable for now. We can change it later.
-	hook.StacktraceConfiguration.Context = 12
+var formattersMutex sync.Mutex
+var formatters = map[string]logrus.Formatter{
+	"text": new(logrus.TextFormatter),
+	"json": new(logrus.JSONFormatter),
+}
 
-	// 4 is the magic number to use so the stack starts where logger.Error(... was used
-	hook.StacktraceConfiguration.Skip = 4
+/*
+RegisterFormatter registers a logrus.Formatter under the given name, so it can be selected with LOGGER_FORMAT
+without having to fork this package. The built-in names are "text", "json" and "logstash"; registering one of
+those replaces it.
+ */
+func RegisterFormatter(name string, f logrus.Formatter) {
+	formattersMutex.Lock()
+	defer formattersMutex.Unlock()
+	formatters[strings.ToLower(name)] = f
+}
 
-	return hook
+/*
+getFormatterFromEnv returns the logrus.Formatter selected by LOGGER_FORMAT ("text", "json", "logstash", or any name
+previously passed to RegisterFormatter). Defaults to "text" when the variable is absent or unknown.
+
+The registry is consulted first, so RegisterFormatter("logstash", ...) does replace the built-in logstash
+formatter as documented; NewLogstashFormatter is only used as a fallback when "logstash" was not registered,
+since unlike the other built-ins it needs to be instantiated with the current LOGGER_NAME.
+ */
+func getFormatterFromEnv() logrus.Formatter {
+	format := strings.ToLower(os.Getenv("LOGGER_FORMAT"))
+
+	formattersMutex.Lock()
+	f, registered := formatters[format]
+	formattersMutex.Unlock()
+	if registered {
+		return f
+	}
+
+	if format == "logstash" {
+		return NewLogstashFormatter(os.Getenv("LOGGER_NAME"))
+	}
+
+	formattersMutex.Lock()
+	defer formattersMutex.Unlock()
+	return formatters["text"]
 }
 
 /*
@@ -128,6 +218,17 @@ func getLevelFromEnv() (logrus.Level, error) {
 		return logrus.DebugLevel, nil
 	}
 
+	return parseLevelString(levelStr)
+}
+
+/*
+parseLevelString parses one of our four supported level names ("debug", "info", "warning", "error"), used both by
+getLevelFromEnv and by the LOGGER_LEVELS/SetLevel parsing in named.go.
+
+Note: we do not use logrus.ParseLevel because we want to exclude warn, fatal and panic which are not a part of cp
+conventions, and we need to have error messages consistent with what's actually possible.
+ */
+func parseLevelString(levelStr string) (logrus.Level, error) {
 	switch strings.ToLower(levelStr) {
 	case "error":
 		return logrus.ErrorLevel, nil
@@ -151,15 +252,24 @@ func CreateLogger() *logrus.Logger {
 
 	newLogger := &logrus.Logger{
 		Out:       os.Stdout,
-		Formatter: new(logrus.TextFormatter),
+		Formatter: getFormatterFromEnv(),
 		Hooks:     make(logrus.LevelHooks),
 		Level:     level,
 	}
 
 	sentryDsn := os.Getenv("SENTRY_DSN")
 	if sentryDsn != "" {
-		hook := createSentryHook(sentryDsn)
-		newLogger.Hooks.Add(hook)
+		newLogger.Hooks.Add(createSentryHook(sentryDsn))
+	}
+
+	if logstashHook := createLogstashHookFromEnv(); logstashHook != nil {
+		newLogger.Hooks.Add(logstashHook)
+		registerActiveHook(logstashHook)
+	}
+
+	if logentriesHook := createLogentriesHookFromEnv(); logentriesHook != nil {
+		newLogger.Hooks.Add(logentriesHook)
+		registerActiveHook(logentriesHook)
 	}
 
 	if levelParseErr != nil {
@@ -187,6 +297,8 @@ ReloadConfiguration reloads configuration from the environment. Mostly useful fo
  */
 func ReloadConfiguration() {
 	logger = nil
+	closeActiveHooks()
+	resetNamedLoggers()
 }
 
 /*
@@ -171,6 +171,34 @@ func TestGetLevelFromEnv_InvalidLogrus(t *testing.T) {
 	})
 }
 /*
+Tests that getFormatterFromEnv falls back to the text formatter when LOGGER_FORMAT is unset or unknown
+*/
+func TestGetFormatterFromEnv_Default(t *testing.T) {
+	withEnvVariable("LOGGER_FORMAT", "", func() {
+		assert.IsType(t, new(logrus.TextFormatter), getFormatterFromEnv())
+	})
+	withEnvVariable("LOGGER_FORMAT", "nonsense", func() {
+		assert.IsType(t, new(logrus.TextFormatter), getFormatterFromEnv())
+	})
+}
+/*
+Tests that RegisterFormatter makes a custom formatter selectable through LOGGER_FORMAT, including overriding the
+built-in "logstash" name as documented
+*/
+func TestRegisterFormatter_Override(t *testing.T) {
+	custom := new(logrus.JSONFormatter)
+	RegisterFormatter("custom", custom)
+	withEnvVariable("LOGGER_FORMAT", "custom", func() {
+		assert.Equal(t, logrus.Formatter(custom), getFormatterFromEnv())
+	})
+
+	RegisterFormatter("logstash", custom)
+	withEnvVariable("LOGGER_FORMAT", "logstash", func() {
+		assert.Equal(t, logrus.Formatter(custom), getFormatterFromEnv())
+	})
+	delete(formatters, "logstash")
+}
+/*
 Tests that with the default setup, info messages and params appear on stdout
  */
 func TestInfo_Local(t *testing.T) {
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	logstashDefaultProtocol = "tcp"
+	logstashBufferSize      = 1024
+	logstashDialTimeout     = 5 * time.Second
+	logstashReconnectDelay  = time.Second
+)
+
+/*
+LogstashFormatter formats log entries as Logstash-compatible JSON objects: fields are flattened at the top level,
+and @timestamp, @version, message, level and host are added per the Logstash JSON event schema. When a non-empty
+Type is set (typically from LOGGER_NAME), it is also added as "type".
+ */
+type LogstashFormatter struct {
+	Type string
+	host string
+}
+
+/*
+NewLogstashFormatter creates a LogstashFormatter tagging every entry with loggerType (used as "type") and the
+local hostname, as reported by os.Hostname().
+ */
+func NewLogstashFormatter(loggerType string) *LogstashFormatter {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &LogstashFormatter{Type: loggerType, host: host}
+}
+
+/*
+Format implements logrus.Formatter
+ */
+func (f *LogstashFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	fields := make(logrus.Fields, len(entry.Data)+5)
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+
+	fields["@timestamp"] = entry.Time.Format(time.RFC3339Nano)
+	fields["@version"] = "1"
+	fields["message"] = entry.Message
+	fields["level"] = entry.Level.String()
+	fields["host"] = f.host
+	if f.Type != "" {
+		fields["type"] = f.Type
+	}
+
+	serialized, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON, %v", err)
+	}
+	return append(serialized, '\n'), nil
+}
+
+/*
+LogstashHook ships each formatted log entry to a remote Logstash instance as a newline-delimited JSON frame, over
+a TCP or UDP connection. Entries are queued in a bounded in-memory buffer and written from a background goroutine
+that reconnects with a fixed backoff whenever the connection is lost, so a downed Logstash instance never blocks
+callers; entries are dropped once the buffer is full.
+ */
+type LogstashHook struct {
+	addr      string
+	protocol  string
+	formatter logrus.Formatter
+	entries   chan []byte
+	stop      chan struct{}
+}
+
+/*
+createLogstashHookFromEnv builds a LogstashHook from LOGSTASH_HOST/LOGSTASH_PORT/LOGSTASH_PROTOCOL, tagged with
+LOGGER_NAME. It returns nil when LOGSTASH_HOST is not set.
+ */
+func createLogstashHookFromEnv() *LogstashHook {
+	host := os.Getenv("LOGSTASH_HOST")
+	if host == "" {
+		return nil
+	}
+	port := os.Getenv("LOGSTASH_PORT")
+
+	protocol := os.Getenv("LOGSTASH_PROTOCOL")
+	if protocol == "" {
+		protocol = logstashDefaultProtocol
+	}
+
+	return NewLogstashHook(protocol, fmt.Sprintf("%s:%s", host, port), NewLogstashFormatter(os.Getenv("LOGGER_NAME")))
+}
+
+/*
+NewLogstashHook creates a LogstashHook shipping entries formatted with formatter to addr over protocol ("tcp" or
+"udp"). It starts the background delivery goroutine immediately.
+ */
+func NewLogstashHook(protocol string, addr string, formatter logrus.Formatter) *LogstashHook {
+	hook := &LogstashHook{
+		addr:      addr,
+		protocol:  protocol,
+		formatter: formatter,
+		entries:   make(chan []byte, logstashBufferSize),
+		stop:      make(chan struct{}),
+	}
+	go hook.run()
+	return hook
+}
+
+/*
+Levels implements logrus.Hook: every level is shipped to Logstash
+ */
+func (hook *LogstashHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+/*
+Fire implements logrus.Hook: it formats the entry and enqueues it without blocking, dropping it if the buffer is
+already full
+ */
+func (hook *LogstashHook) Fire(entry *logrus.Entry) error {
+	serialized, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case hook.entries <- serialized:
+	default:
+		// buffer full: drop the entry rather than block the caller
+	}
+	return nil
+}
+
+/*
+run owns the connection to Logstash. It dials lazily on the first queued entry and redials with a fixed backoff
+whenever a write fails; entries queued while disconnected are dropped rather than buffered indefinitely. It exits,
+closing the connection, as soon as Close is called.
+ */
+func (hook *LogstashHook) run() {
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for {
+		select {
+		case serialized := <-hook.entries:
+			if conn == nil {
+				var err error
+				conn, err = net.DialTimeout(hook.protocol, hook.addr, logstashDialTimeout)
+				if err != nil {
+					conn = nil
+					select {
+					case <-time.After(logstashReconnectDelay):
+					case <-hook.stop:
+						return
+					}
+					continue
+				}
+			}
+
+			if _, err := conn.Write(serialized); err != nil {
+				conn.Close()
+				conn = nil
+			}
+		case <-hook.stop:
+			return
+		}
+	}
+}
+
+/*
+Close stops the background delivery goroutine and closes any open connection. Meant for retiring a hook that is
+no longer reachable from any logger (e.g. when ReloadConfiguration replaces the singleton); entries queued after
+Close are simply never delivered.
+ */
+func (hook *LogstashHook) Close() {
+	close(hook.stop)
+}
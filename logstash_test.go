@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Tests that LogstashFormatter flattens fields and adds the Logstash envelope (@timestamp, @version, message, level,
+host and type)
+ */
+func TestLogstashFormatter_Format(t *testing.T) {
+	formatter := NewLogstashFormatter("billing")
+
+	entry := &logrus.Entry{
+		Time:    time.Now(),
+		Level:   logrus.InfoLevel,
+		Message: "test logstash",
+		Data:    logrus.Fields{"count": 1},
+	}
+
+	serialized, err := formatter.Format(entry)
+	assert.Nil(t, err)
+
+	var parsed map[string]interface{}
+	assert.Nil(t, json.Unmarshal(serialized, &parsed))
+
+	assert.Equal(t, "test logstash", parsed["message"])
+	assert.Equal(t, "info", parsed["level"])
+	assert.Equal(t, "billing", parsed["type"])
+	assert.EqualValues(t, 1, parsed["count"])
+	assert.Equal(t, "1", parsed["@version"])
+	assert.NotEmpty(t, parsed["@timestamp"])
+	assert.NotEmpty(t, parsed["host"])
+}
+
+/*
+Tests that a LogstashHook with no reachable Logstash instance never blocks Fire
+ */
+func TestLogstashHook_DoesNotBlockWhenUnreachable(t *testing.T) {
+	hook := NewLogstashHook("tcp", "127.0.0.1:1", NewLogstashFormatter("billing"))
+	defer hook.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < logstashBufferSize+10; i++ {
+			hook.Fire(&logrus.Entry{Message: "overflow", Data: logrus.Fields{}})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Fire blocked on an unreachable Logstash instance")
+	}
+}
+
+/*
+Tests that a LogstashHook delivers newline-delimited JSON frames to a real TCP listener
+ */
+func TestLogstashHook_DeliversToTCPListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 4096)
+		n, _ := conn.Read(buf)
+		received <- buf[:n]
+	}()
+
+	hook := NewLogstashHook("tcp", listener.Addr().String(), NewLogstashFormatter("billing"))
+	hook.Fire(&logrus.Entry{Message: "shipped", Data: logrus.Fields{}})
+
+	select {
+	case data := <-received:
+		assert.Contains(t, string(data), "shipped")
+	case <-time.After(2 * time.Second):
+		t.Fatal("entry was never delivered to the Logstash listener")
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// namedMutex guards namedLevels and namedLoggers below.
+var namedMutex sync.RWMutex
+
+// namedLevels holds the explicit levels set for a given dotted name, either seeded from LOGGER_LEVELS or set at
+// runtime through SetLevel. Names without an entry here inherit their level from their nearest ancestor.
+var namedLevels = map[string]logrus.Level{}
+
+// namedLoggers caches one *logrus.Logger per name, so repeated calls to GetNamed reuse the same instance and its
+// Level can be updated in place when SetLevel/ResetLevels run.
+var namedLoggers = map[string]*logrus.Logger{}
+
+func init() {
+	namedMutex.Lock()
+	loadLevelsFromEnvLocked()
+	namedMutex.Unlock()
+}
+
+/*
+GetNamed returns the logrus.Entry for the sub-logger registered under name (e.g. "billing.invoicing.pdf"). Names
+are normalized to lowercase. The returned entry always carries a "logger" field set to the normalized name, so
+Sentry/Logstash can filter by subsystem.
+
+The sub-logger's effective level is resolved the first time a given name is requested, and again whenever
+SetLevel or ResetLevels run, from its nearest dotted ancestor with an explicit level (see SetLevel and the LOGGER_LEVELS environment variable), defaulting to
+the root's LOGGER_LEVEL. It shares the root logger's Out, Formatter and Hooks.
+
+Cache hits take namedMutex only for a read lock, so hot-path logging through an already-created named logger does
+not serialize with other goroutines doing the same; only the first call for a given name takes the write lock to
+create it.
+ */
+func GetNamed(name string) *logrus.Entry {
+	normalized := strings.ToLower(name)
+
+	namedMutex.RLock()
+	namedLogger, ok := namedLoggers[normalized]
+	namedMutex.RUnlock()
+	if ok {
+		return namedLogger.WithField("logger", normalized)
+	}
+
+	namedMutex.Lock()
+	namedLogger, ok = namedLoggers[normalized]
+	if !ok {
+		root := GetLogger()
+		namedLogger = &logrus.Logger{
+			Out:       root.Out,
+			Formatter: root.Formatter,
+			Hooks:     root.Hooks,
+			Level:     effectiveLevelLocked(normalized),
+		}
+		namedLoggers[normalized] = namedLogger
+	}
+	namedMutex.Unlock()
+
+	return namedLogger.WithField("logger", normalized)
+}
+
+/*
+SetLevel sets the effective level of name, and of every descendant that does not have its own explicit level, to
+level. name is normalized to lowercase; use "root" to change the fallback applied to every name with no matching
+entry in the tree.
+ */
+func SetLevel(name string, level logrus.Level) {
+	namedMutex.Lock()
+	defer namedMutex.Unlock()
+	namedLevels[strings.ToLower(name)] = level
+	applyLevelsToLoggersLocked()
+}
+
+/*
+ResetLevels discards every level set through SetLevel or LOGGER_LEVELS, then reloads LOGGER_LEVELS from the
+environment. Mostly useful for tests.
+ */
+func ResetLevels() {
+	namedMutex.Lock()
+	defer namedMutex.Unlock()
+	namedLevels = map[string]logrus.Level{}
+	loadLevelsFromEnvLocked()
+	applyLevelsToLoggersLocked()
+}
+
+func resetNamedLoggers() {
+	namedMutex.Lock()
+	namedLoggers = map[string]*logrus.Logger{}
+	namedMutex.Unlock()
+}
+
+/*
+loadLevelsFromEnvLocked parses LOGGER_LEVELS ("root=info,billing=debug,billing.invoicing=warning") into
+namedLevels. Malformed pairs and unknown level names are silently ignored, since getLevelFromEnv's stricter
+validation already covers the common case of a single misconfigured LOGGER_LEVEL.
+
+Caller must hold namedMutex.
+ */
+func loadLevelsFromEnvLocked() {
+	raw := os.Getenv("LOGGER_LEVELS")
+	if raw == "" {
+		return
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		level, err := parseLevelString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			continue
+		}
+		namedLevels[strings.ToLower(strings.TrimSpace(parts[0]))] = level
+	}
+}
+
+/*
+ancestorsOf returns name's dotted ancestors from the most specific to the least, ending with "root":
+ancestorsOf("billing.invoicing.pdf") is ["billing.invoicing.pdf", "billing.invoicing", "billing", "root"].
+ */
+func ancestorsOf(name string) []string {
+	if name == "" || name == "root" {
+		return []string{"root"}
+	}
+
+	parts := strings.Split(name, ".")
+	ancestors := make([]string, 0, len(parts)+1)
+	for depth := len(parts); depth > 0; depth-- {
+		ancestors = append(ancestors, strings.Join(parts[:depth], "."))
+	}
+	return append(ancestors, "root")
+}
+
+/*
+effectiveLevelLocked resolves name's level by walking up to its nearest ancestor with an explicit entry in
+namedLevels, falling back to LOGGER_LEVEL. This is O(depth): ancestorsOf returns one name per dotted segment of
+name, plus "root".
+
+Caller must hold namedMutex.
+ */
+func effectiveLevelLocked(name string) logrus.Level {
+	for _, ancestor := range ancestorsOf(name) {
+		if level, ok := namedLevels[ancestor]; ok {
+			return level
+		}
+	}
+
+	rootLevel, _ := getLevelFromEnv()
+	return rootLevel
+}
+
+/*
+applyLevelsToLoggersLocked refreshes the Level field of every cached named logger after the level tree changed.
+
+Caller must hold namedMutex.
+ */
+func applyLevelsToLoggersLocked() {
+	for name, namedLogger := range namedLoggers {
+		namedLogger.Level = effectiveLevelLocked(name)
+	}
+}
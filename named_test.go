@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Tests that a named logger inherits its level from the nearest ancestor set through LOGGER_LEVELS
+ */
+func TestGetNamed_InheritsAncestorLevel(t *testing.T) {
+	withEnvVariable("LOGGER_LEVELS", "billing=warning", func() {
+		stdout := captureStdout(func() {
+			defer ResetLevels()
+			ResetLevels()
+
+			GetNamed("billing.invoicing").Info("should be filtered")
+			GetNamed("billing.invoicing").Warning("should appear")
+		})
+
+		assert.NotContains(t, string(stdout), "should be filtered")
+		assert.Contains(t, string(stdout), "should appear")
+		assert.Contains(t, string(stdout), `logger=billing.invoicing`)
+	})
+}
+
+/*
+Tests that a name with no matching entry in LOGGER_LEVELS falls back to LOGGER_LEVEL
+ */
+func TestGetNamed_DefaultsToRootLevel(t *testing.T) {
+	withEnvVariable("LOGGER_LEVEL", "info", func() {
+		stdout := captureStdout(func() {
+			defer ResetLevels()
+			ResetLevels()
+
+			GetNamed("unrelated.subsystem").Debug("should be filtered")
+			GetNamed("unrelated.subsystem").Info("should appear")
+		})
+
+		assert.NotContains(t, string(stdout), "should be filtered")
+		assert.Contains(t, string(stdout), "should appear")
+	})
+}
+
+/*
+Tests that SetLevel overrides the level of a name and its descendants at runtime
+ */
+func TestSetLevel_OverridesAtRuntime(t *testing.T) {
+	stdout := captureStdout(func() {
+		defer ResetLevels()
+		ResetLevels()
+
+		SetLevel("billing", logrus.ErrorLevel)
+
+		GetNamed("billing.invoicing").Warning("should be filtered")
+		GetNamed("billing.invoicing").Error("should appear")
+	})
+
+	assert.NotContains(t, string(stdout), "should be filtered")
+	assert.Contains(t, string(stdout), "should appear")
+}
+
+/*
+Tests that names are normalized to lowercase in the "logger" field
+ */
+func TestGetNamed_NormalizesNameToLowercase(t *testing.T) {
+	stdout := captureStdout(func() {
+		defer ResetLevels()
+		ResetLevels()
+
+		GetNamed("Billing.Invoicing").Info("mixed case name")
+	})
+
+	assert.Contains(t, string(stdout), `logger=billing.invoicing`)
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/evalphobia/logrus_sentry"
+	"github.com/getsentry/raven-go"
+)
+
+// sentryHookLevels is the set of levels the Sentry hook subscribes to: we only ever want to be paged for
+// warnings or worse.
+var sentryHookLevels = []logrus.Level{
+	logrus.PanicLevel,
+	logrus.FatalLevel,
+	logrus.ErrorLevel,
+	logrus.WarnLevel,
+}
+
+/*
+createSentryHook creates a sentry hook catching messages of level warning or worse and sending them to sentry,
+configured from the environment (see package overview). The magic fields server_name, logger, http_request and
+event_id are promoted out of Extra into the corresponding Sentry fields natively by logrus_sentry, on a per-entry
+basis, so they never leak into or out of unrelated events.
+ */
+func createSentryHook(sentryDsn string) logrus.Hook {
+	hook, err := logrus_sentry.NewSentryHook(sentryDsn, sentryHookLevels)
+	if err != nil {
+		panic(err)
+	}
+	configureSentryHookFromEnv(hook)
+	return hook
+}
+
+/*
+NewSentryHookWithClient builds the same warning/error Sentry hook CreateLogger wires in when SENTRY_DSN is set, but
+against an already-configured raven.Client instead of building one from a DSN. This lets applications share a
+single raven.Client (and its DSN, release, tags...) between this package and the rest of their code, and makes the
+hook easy to exercise in tests against a fake client.
+ */
+func NewSentryHookWithClient(client *raven.Client) (logrus.Hook, error) {
+	hook, err := logrus_sentry.NewWithClientSentryHook(client, sentryHookLevels)
+	if err != nil {
+		return nil, err
+	}
+	configureSentryHookFromEnv(hook)
+	return hook, nil
+}
+
+/*
+configureSentryHookFromEnv applies SENTRY_TIMEOUT, SENTRY_STACKTRACE_*, SENTRY_TAGS, SENTRY_RELEASE,
+SENTRY_ENVIRONMENT and SENTRY_SERVER_NAME to hook, falling back to this package's historical defaults (1s timeout,
+error-level stacktraces with 12 lines of context and 4 frames skipped) when a variable is absent or invalid.
+ */
+func configureSentryHookFromEnv(hook *logrus_sentry.SentryHook) {
+	hook.Timeout = durationFromEnv("SENTRY_TIMEOUT", time.Second)
+
+	hook.StacktraceConfiguration.Enable = true
+	hook.StacktraceConfiguration.Level = levelFromEnv("SENTRY_STACKTRACE_LEVEL", logrus.ErrorLevel)
+	hook.StacktraceConfiguration.Context = intFromEnv("SENTRY_STACKTRACE_CONTEXT", 12)
+	hook.StacktraceConfiguration.Skip = intFromEnv("SENTRY_STACKTRACE_SKIP", 4)
+
+	if release := os.Getenv("SENTRY_RELEASE"); release != "" {
+		hook.Client.SetRelease(release)
+	}
+	if environment := os.Getenv("SENTRY_ENVIRONMENT"); environment != "" {
+		hook.Client.SetEnvironment(environment)
+	}
+	if serverName := os.Getenv("SENTRY_SERVER_NAME"); serverName != "" {
+		hook.Client.SetServerName(serverName)
+	}
+	if tags := sentryTagsFromEnv(); len(tags) > 0 {
+		hook.Client.SetTagsContext(tags)
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func levelFromEnv(key string, fallback logrus.Level) logrus.Level {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	level, err := parseLevelString(raw)
+	if err != nil {
+		return fallback
+	}
+	return level
+}
+
+func sentryTagsFromEnv() map[string]string {
+	return sentryTagsFromEnvKey("SENTRY_TAGS")
+}
+
+func sentryTagsFromEnvKey(key string) map[string]string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+
+	tags := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		tags[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return tags
+}
+
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Tests that the SENTRY_* helpers fall back to this package's historical defaults when absent or invalid, and parse
+the documented formats otherwise
+ */
+func TestSentryEnvHelpers(t *testing.T) {
+	assert.Equal(t, 12, intFromEnv("SENTRY_STACKTRACE_CONTEXT_TEST", 12))
+	withEnvVariable("SENTRY_STACKTRACE_CONTEXT_TEST", "3", func() {
+		assert.Equal(t, 3, intFromEnv("SENTRY_STACKTRACE_CONTEXT_TEST", 12))
+	})
+	withEnvVariable("SENTRY_STACKTRACE_CONTEXT_TEST", "not a number", func() {
+		assert.Equal(t, 12, intFromEnv("SENTRY_STACKTRACE_CONTEXT_TEST", 12))
+	})
+
+	withEnvVariable("SENTRY_TAGS_TEST", "region=eu,az=eu-west-1a", func() {
+		tags := map[string]string{"region": "eu", "az": "eu-west-1a"}
+		assert.Equal(t, tags, sentryTagsFromEnvKey("SENTRY_TAGS_TEST"))
+	})
+}
+
+/*
+Tests that server_name and logger are removed from the Extra payload shipped to Sentry, since logrus_sentry
+promotes them to dedicated Sentry fields instead, and that this promotion is genuinely per-entry: an unrelated
+entry fired afterwards must not inherit the previous entry's server_name/logger.
+ */
+func TestSentryHook_PromotesMagicFields(t *testing.T) {
+	ts := startMockSentryServer(t)
+	defer ts.Server.Close()
+
+	withEnvVariable("SENTRY_DSN", "http://aaa:bbb@"+ts.Host+"/123", func() {
+		ReloadConfiguration()
+
+		WithFields(logrus.Fields{
+			"name":        "str param",
+			"server_name": "worker-3",
+			"logger":      "billing",
+		}).Error("test sentry magic fields")
+		firstPacket := <-ts.PacketChannel
+		assert.Equal(t, "str param", firstPacket.Extra["name"])
+		assert.NotContains(t, firstPacket.Extra, "server_name")
+		assert.NotContains(t, firstPacket.Extra, "logger")
+
+		WithFields(logrus.Fields{
+			"name": "unrelated",
+		}).Error("test sentry does not leak previous magic fields")
+		secondPacket := <-ts.PacketChannel
+
+		assert.NotEqual(t, "worker-3", secondPacket.ServerName)
+		assert.NotEqual(t, "billing", secondPacket.Logger)
+	})
+	ReloadConfiguration()
+}